@@ -0,0 +1,37 @@
+package csvx
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+func TestWithCharsetDecodesUTF16(t *testing.T) {
+	enc := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+
+	encoded, _, err := transform.Bytes(enc.NewEncoder(), []byte("a,b\n1,2\n"))
+	if err != nil {
+		t.Fatalf("encoding fixture: %v", err)
+	}
+
+	rd, err := NewReader(FromReader(bytes.NewReader(encoded)), WithCharset("utf-16"))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	defer rd.Close()
+
+	if !rd.Next() {
+		t.Fatalf("Next: expected a row, got none (err=%v)", rd.err)
+	}
+
+	var a, b string
+	if err := rd.Scan(&a, &b); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	if a != "1" || b != "2" {
+		t.Errorf("Scan: got a=%q b=%q, want a=%q b=%q", a, b, "1", "2")
+	}
+}
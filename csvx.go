@@ -1,35 +1,80 @@
 package csvx
 
 import (
+	"compress/bzip2"
 	"compress/gzip"
 	"encoding"
 	"encoding/csv"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mitchellh/ioprogress"
 	"github.com/pkg/errors"
 
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+
 	"fknsrs.biz/p/civil"
 	"fknsrs.biz/p/timex"
 )
 
+// decompressors maps a file extension (including the leading dot) to a
+// function that wraps a reader of compressed bytes in a reader of the
+// decompressed stream. FromFile consults this registry to decide how to
+// handle a given filename. .gz and .bz2 are registered out of the box,
+// since both have decoders in the standard library; register additional
+// formats (e.g. .zst, .xz) with RegisterDecompressor.
+var decompressors = map[string]func(io.Reader) (io.Reader, error){
+	".gz": func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	},
+	".bz2": func(r io.Reader) (io.Reader, error) {
+		return bzip2.NewReader(r), nil
+	},
+}
+
+// RegisterDecompressor registers a decompressor for files whose name ends
+// in ext (e.g. ".zst", ".xz"), for use by FromFile. It is typically
+// called from the init function of a package that wraps a third-party
+// decompression library, since the standard library's own formats (.gz,
+// .bz2) are already registered, e.g.:
+//
+//	func init() {
+//		csvx.RegisterDecompressor(".zst", func(r io.Reader) (io.Reader, error) {
+//			return zstd.NewReader(r)
+//		})
+//	}
+//
+// Registering a decompressor for an extension that's already registered
+// replaces the existing one.
+func RegisterDecompressor(ext string, fn func(io.Reader) (io.Reader, error)) {
+	decompressors[ext] = fn
+}
+
 type Scanner interface {
 	ScanString(s string) error
 }
 
 type Reader struct {
-	fd  io.Reader
-	rd  *csv.Reader
-	hdr []string
-	row []string
-	err error
-	cl  func() error
-	tz  *time.Location
+	fd         io.Reader
+	rd         *csv.Reader
+	hdr        []string
+	row        []string
+	err        error
+	cl         func() error
+	tz         *time.Location
+	workers    int
+	line       int
+	errHandler func(RowError) error
+	dialect    *Dialect
 }
 
 type Option func(rd *Reader) error
@@ -44,13 +89,13 @@ func FromFile(filename string) Option {
 		rd.fd = fd
 		rd.cl = fd.Close
 
-		if strings.HasSuffix(filename, ".gz") {
-			gz, err := gzip.NewReader(rd.fd)
+		if fn, ok := decompressors[filepath.Ext(filename)]; ok {
+			dr, err := fn(rd.fd)
 			if err != nil {
 				return errors.Wrap(err, "csvx.FromPath")
 			}
 
-			rd.fd = gz
+			rd.fd = dr
 		}
 
 		return nil
@@ -77,6 +122,52 @@ func WithTZ(tz *time.Location) Option {
 	}
 }
 
+// WithWorkers sets the number of goroutines used to decode rows when the
+// Reader is driven through Decode or Stream. Values less than 1 are
+// treated as 1 (no parallelism). It has no effect on Next/Scan/ScanStruct,
+// which always run on the calling goroutine.
+func WithWorkers(n int) Option {
+	return func(rd *Reader) error {
+		rd.workers = n
+
+		return nil
+	}
+}
+
+// WithErrorHandler installs a handler that's given the chance to recover
+// from a column-level error in Scan or ScanStruct, instead of it aborting
+// the row. Returning nil from fn treats the row as handled (e.g. after
+// logging it) and Scan/ScanStruct returns nil; returning a non-nil error
+// (fn's argument or one of its own) aborts the row as usual.
+func WithErrorHandler(fn func(RowError) error) Option {
+	return func(rd *Reader) error {
+		rd.errHandler = fn
+
+		return nil
+	}
+}
+
+// WithCharset wraps the reader with a decoder for the named character
+// encoding, looked up via golang.org/x/text/encoding/htmlindex (e.g.
+// "gbk", "shift_jis", "windows-1252"), for CSVs sourced from systems that
+// don't produce UTF-8. A byte-order mark, if present, is sniffed first and
+// takes precedence over enc, matching how most of those systems actually
+// tag their output; either way the BOM is stripped rather than ending up
+// prefixed onto the header row. WithCharset must come after FromFile or
+// FromReader in the option list, since it wraps whatever reader those set.
+func WithCharset(enc string) Option {
+	return func(rd *Reader) error {
+		e, err := htmlindex.Get(enc)
+		if err != nil {
+			return errors.Wrapf(err, "csvx.WithCharset(%q)", enc)
+		}
+
+		rd.fd = transform.NewReader(rd.fd, unicode.BOMOverride(e.NewDecoder()))
+
+		return nil
+	}
+}
+
 type canStat interface {
 	Stat() (os.FileInfo, error)
 }
@@ -122,18 +213,30 @@ func NewReader(opts ...Option) (*Reader, error) {
 
 	r.rd = csv.NewReader(r.fd)
 
+	if d := r.dialect; d != nil {
+		if d.Comma != 0 {
+			r.rd.Comma = d.Comma
+		}
+		r.rd.Comment = d.Comment
+		r.rd.LazyQuotes = d.LazyQuotes
+		r.rd.TrimLeadingSpace = d.TrimLeadingSpace
+		r.rd.FieldsPerRecord = d.FieldsPerRecord
+	}
+
 	hdr, err := r.rd.Read()
 	if err != nil {
 		return r, errors.Wrap(err, "csvx.NewReader: couldn't read header")
 	}
 
 	r.hdr = hdr
+	r.line = 1
 
 	return r, nil
 }
 
 func (r *Reader) Next() bool {
 	r.row, r.err = r.rd.Read()
+	r.line++
 	if r.err == io.EOF {
 		r.err = nil
 		return false
@@ -142,133 +245,293 @@ func (r *Reader) Next() bool {
 	return true
 }
 
+// Line returns the line number of the row last returned by Next, counting
+// the header as line 1. It's meant for post-mortem inspection after an
+// error, alongside Row.
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Row returns the raw fields of the row last returned by Next.
+func (r *Reader) Row() []string {
+	return r.row
+}
+
+// RowError wraps an error encountered while scanning a single column of a
+// row, with enough context (line number, column index and name, and the
+// raw cell value) to debug it without re-running the parse by hand.
+type RowError struct {
+	Line       int
+	Column     int
+	ColumnName string
+	RawValue   string
+	Err        error
+}
+
+func (e *RowError) Error() string {
+	if e.Column < 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+	}
+
+	name := e.ColumnName
+	if name == "" {
+		name = strconv.Itoa(e.Column)
+	}
+
+	return fmt.Sprintf("line %d, column %s (%q): %s", e.Line, name, e.RawValue, e.Err)
+}
+
+// Cause lets errors.Cause (github.com/pkg/errors) unwrap a RowError back
+// to the underlying parse error.
+func (e *RowError) Cause() error {
+	return e.Err
+}
+
+// columnError is how scanRow/scanStructRow report which column failed,
+// without knowing the line number or column name that only a Reader has.
+// Reader.Scan and Reader.ScanStruct turn it into a RowError.
+type columnError struct {
+	index int
+	value string
+	err   error
+}
+
+func (e *columnError) Error() string {
+	return e.err.Error()
+}
+
+func (e *columnError) Cause() error {
+	return e.err
+}
+
+// rowReadError marks an error that happened while reading a row's raw
+// fields (e.g. encoding/csv rejecting a malformed line), as opposed to
+// decoding an already-read field. rowError treats it like a columnError -
+// a data problem worth offering to errHandler - rather than passing it
+// through unchanged the way it does for other, structural errors.
+type rowReadError struct {
+	err error
+}
+
+func (e *rowReadError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rowReadError) Cause() error {
+	return e.err
+}
+
+// rowError turns a columnError or rowReadError from scanning line into a
+// RowError, routing it through errHandler if one was installed via
+// WithErrorHandler. Any other error (e.g. a caller passing the wrong type
+// to ScanStruct) is a programmer mistake rather than a data problem, and
+// is returned unchanged so it can't be silently swallowed by a handler
+// meant for bad rows.
+func (r *Reader) rowError(line int, err error) error {
+	re := &RowError{Line: line, Column: -1, Err: err}
+
+	switch e := err.(type) {
+	case *columnError:
+		re.Column = e.index
+		re.RawValue = e.value
+		re.Err = e.err
+
+		if e.index < len(r.hdr) {
+			re.ColumnName = r.hdr[e.index]
+		}
+	case *rowReadError:
+		re.Err = e.err
+	default:
+		return err
+	}
+
+	if r.errHandler == nil {
+		return re
+	}
+
+	return r.errHandler(*re)
+}
+
 func (r *Reader) Scan(out ...interface{}) error {
 	if r.err != nil {
 		return r.err
 	}
 
+	if err := scanRow(r.row, r.tz, out...); err != nil {
+		return r.rowError(r.line, err)
+	}
+
+	return nil
+}
+
+// scanRow implements the decoding logic behind Reader.Scan, but operates on
+// a plain row and timezone instead of reading Reader state. This lets it be
+// reused by decodeInto, where rows are handed out to worker goroutines and
+// there's no single Reader whose fields could be shared safely.
+//
+// Every column is scanned regardless of whether an earlier one failed, so
+// that a caller whose error handler decides to keep a row still gets every
+// field CSV actually gave it, rather than zero values past the first
+// failure. Only the first error is kept and returned, to preserve the
+// existing single-error return value.
+func scanRow(row []string, tz *time.Location, out ...interface{}) error {
+	var firstErr *columnError
+
 	for i, e := range out {
 		c := ""
 
-		if len(r.row) > i {
-			c = strings.TrimSpace(r.row[i])
+		if len(row) > i {
+			c = strings.TrimSpace(row[i])
+		}
+
+		if err := scanField(c, e, tz); err != nil {
+			if firstErr == nil {
+				firstErr = &columnError{index: i, value: c, err: err}
+			}
 		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
 
-		switch e := e.(type) {
-		case nil:
-			// nothing
-		case *string:
-			*e = c
-		case *int:
+	return nil
+}
+
+// scanField decodes a single cell into e, as one case of the switch Scan
+// has always used. It's split out of scanRow so that a failure on one
+// column doesn't stop the rest of the row from being scanned.
+func scanField(c string, e interface{}, tz *time.Location) error {
+	switch e := e.(type) {
+	case nil:
+		// nothing
+	case *string:
+		*e = c
+	case *int:
+		if c == "" {
+			*e = 0
+		} else {
 			n, err := strconv.ParseInt(c, 10, 64)
 			if err != nil {
-				return errors.Wrapf(err, "csvx.Reader.Scan(%T) (index %d)", e, i)
+				return errors.Wrapf(err, "csvx.Reader.Scan(%T)", e)
 			}
 			*e = int(n)
-		case **int:
-			if c == "" {
-				*e = nil
-			} else {
-				n, err := strconv.ParseInt(c, 10, 64)
-				if err != nil {
-					return errors.Wrapf(err, "csvx.Reader.Scan(%T) (index %d)", e, i)
-				}
-				v := int(n)
-				*e = &v
+		}
+	case **int:
+		if c == "" {
+			*e = nil
+		} else {
+			n, err := strconv.ParseInt(c, 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "csvx.Reader.Scan(%T)", e)
 			}
-		case *float64:
+			v := int(n)
+			*e = &v
+		}
+	case *float64:
+		if c == "" {
+			*e = 0
+		} else {
 			n, err := strconv.ParseFloat(c, 64)
 			if err != nil {
-				return errors.Wrapf(err, "csvx.Reader.Scan(%T) (index %d)", e, i)
+				return errors.Wrapf(err, "csvx.Reader.Scan(%T)", e)
 			}
 			*e = n
-		case **float64:
-			if c == "" {
-				*e = nil
-			} else {
-				n, err := strconv.ParseFloat(c, 64)
-				if err != nil {
-					return errors.Wrapf(err, "csvx.Reader.Scan(%T) (index %d)", e, i)
-				}
-				*e = &n
+		}
+	case **float64:
+		if c == "" {
+			*e = nil
+		} else {
+			n, err := strconv.ParseFloat(c, 64)
+			if err != nil {
+				return errors.Wrapf(err, "csvx.Reader.Scan(%T)", e)
 			}
-		case *time.Time:
-			t, err := timex.ParseDefaultsInLocation(c, r.tz)
+			*e = &n
+		}
+	case *time.Time:
+		if c == "" {
+			*e = time.Time{}
+		} else {
+			t, err := timex.ParseDefaultsInLocation(c, tz)
 			if err != nil {
-				return errors.Wrapf(err, "csvx.Reader.Scan(%T) (index %d)", e, i)
+				return errors.Wrapf(err, "csvx.Reader.Scan(%T)", e)
 			}
 			*e = t
-		case **time.Time:
-			if c == "" {
-				*e = nil
-			} else {
-				t, err := timex.ParseDefaultsInLocation(c, r.tz)
-				if err != nil {
-					return errors.Wrapf(err, "csvx.Reader.Scan(%T) (index %d)", e, i)
-				}
-				*e = &t
+		}
+	case **time.Time:
+		if c == "" {
+			*e = nil
+		} else {
+			t, err := timex.ParseDefaultsInLocation(c, tz)
+			if err != nil {
+				return errors.Wrapf(err, "csvx.Reader.Scan(%T)", e)
 			}
-		case *civil.Date:
+			*e = &t
+		}
+	case *civil.Date:
+		if c == "" {
+			*e = civil.Date{}
+		} else {
 			t, err := civil.ParseDate(c)
 			if err != nil {
-				return errors.Wrapf(err, "csvx.Reader.Scan(%T) (index %d)", e, i)
+				return errors.Wrapf(err, "csvx.Reader.Scan(%T)", e)
 			}
 			*e = t
-		case **civil.Date:
-			if c == "" {
-				*e = nil
-			} else {
-				t, err := civil.ParseDate(c)
-				if err != nil {
-					return errors.Wrapf(err, "csvx.Reader.Scan(%T) (index %d)", e, i)
-				}
-				*e = &t
-			}
-		case *bool:
-			if c == "1" || c == "yes" || c == "true" || c == "t" {
-				*e = true
-			} else if c == "" || c == "0" || c == "no" || c == "false" || c == "f" {
-				*e = false
-			} else {
-				return errors.Errorf("csvx.Reader.Scan(%T) (index %d): couldn't convert %q to boolean", e, i, c)
-			}
-		default:
-			p := reflect.ValueOf(e)
-
-			if p.Type().Kind() != reflect.Ptr {
-				return errors.Errorf("csvx.Reader.Scan(%T) (index %d): can't scan into %T; must be a pointer", e, i, e)
+		}
+	case **civil.Date:
+		if c == "" {
+			*e = nil
+		} else {
+			t, err := civil.ParseDate(c)
+			if err != nil {
+				return errors.Wrapf(err, "csvx.Reader.Scan(%T)", e)
 			}
+			*e = &t
+		}
+	case *bool:
+		if c == "1" || c == "yes" || c == "true" || c == "t" {
+			*e = true
+		} else if c == "" || c == "0" || c == "no" || c == "false" || c == "f" {
+			*e = false
+		} else {
+			return errors.Errorf("csvx.Reader.Scan(%T): couldn't convert %q to boolean", e, c)
+		}
+	default:
+		p := reflect.ValueOf(e)
 
-			if t := p.Type().Elem(); t.Kind() == reflect.Ptr && c == "" {
-				p.Elem().Set(reflect.Zero(t))
-				continue
-			}
+		if p.Type().Kind() != reflect.Ptr {
+			return errors.Errorf("csvx.Reader.Scan(%T): can't scan into %T; must be a pointer", e, e)
+		}
 
-			if p.Type().Elem().Kind() == reflect.Ptr && p.Elem().IsNil() {
-				p.Elem().Set(reflect.New(p.Type().Elem().Elem()))
-				p = p.Elem()
-			}
+		if t := p.Type().Elem(); t.Kind() == reflect.Ptr && c == "" {
+			p.Elem().Set(reflect.Zero(t))
+			return nil
+		}
 
-			v := p.Interface()
+		if p.Type().Elem().Kind() == reflect.Ptr && p.Elem().IsNil() {
+			p.Elem().Set(reflect.New(p.Type().Elem().Elem()))
+			p = p.Elem()
+		}
 
-			if s, ok := v.(Scanner); ok {
-				if err := s.ScanString(c); err != nil {
-					return errors.Wrapf(err, "csvx.Reader.Scan(%T) (index %d)", e, i)
-				}
+		v := p.Interface()
 
-				continue
+		if s, ok := v.(Scanner); ok {
+			if err := s.ScanString(c); err != nil {
+				return errors.Wrapf(err, "csvx.Reader.Scan(%T)", e)
 			}
 
-			if s, ok := v.(encoding.TextUnmarshaler); ok {
-				if err := s.UnmarshalText([]byte(c)); err != nil {
-					return errors.Wrapf(err, "csvx.Reader.Scan(%T) (index %d)", e, i)
-				}
+			return nil
+		}
 
-				continue
+		if s, ok := v.(encoding.TextUnmarshaler); ok {
+			if err := s.UnmarshalText([]byte(c)); err != nil {
+				return errors.Wrapf(err, "csvx.Reader.Scan(%T)", e)
 			}
 
-			return errors.Errorf("csvx.Reader.Scan(%T) (index %d): can't scan into %T", e, i, e)
+			return nil
 		}
+
+		return errors.Errorf("csvx.Reader.Scan(%T): can't scan into %T", e, e)
 	}
 
 	return nil
@@ -279,19 +542,35 @@ func (r *Reader) ScanStruct(out interface{}) error {
 		return r.err
 	}
 
-	ptr := reflect.ValueOf(out)
-	if ptr.Kind() != reflect.Ptr {
-		return errors.Errorf("csvx.Reader.ScanStruct: expected out to be pointer; was instead %s", ptr.Kind())
+	if err := scanStructRow(r.hdr, r.row, r.tz, out); err != nil {
+		return r.rowError(r.line, err)
 	}
 
-	str := reflect.Indirect(ptr)
-	if str.Kind() != reflect.Struct {
-		return errors.Errorf("csvx.Reader.ScanStruct: expected out to be pointer to struct; was instead pointer to %s", str.Kind())
-	}
+	return nil
+}
+
+// structFieldMap maps the fields of a struct type onto the columns of a
+// particular header, so that the (comparatively expensive) tag parsing and
+// name matching done by ScanStruct only has to be redone when a type is
+// used with a different header from last time. Only the most recent
+// header's mapping is cached for each type.
+type structFieldMap struct {
+	hdr     string
+	columns []int
+}
+
+var structFieldMapCache sync.Map // map[reflect.Type]*structFieldMap
 
-	typ := str.Type()
+func structFieldMapFor(typ reflect.Type, hdr []string) (*structFieldMap, error) {
+	key := strings.Join(hdr, "\x00")
+
+	if v, ok := structFieldMapCache.Load(typ); ok {
+		if fm := v.(*structFieldMap); fm.hdr == key {
+			return fm, nil
+		}
+	}
 
-	vars := make([]interface{}, len(r.hdr))
+	columns := make([]int, typ.NumField())
 
 outer:
 	for i := 0; i < typ.NumField(); i++ {
@@ -299,6 +578,7 @@ outer:
 
 		a := strings.Split(f.Tag.Get("csv"), ",")
 		if a[0] == "-" {
+			columns[i] = -1
 			continue
 		}
 
@@ -307,32 +587,251 @@ outer:
 			name = a[0]
 		}
 
-		for j, c := range r.hdr {
+		for j, c := range hdr {
 			if c == name {
-				vars[j] = str.Field(i).Addr().Interface()
+				columns[i] = j
 				continue outer
 			}
 		}
 
-		for j, c := range r.hdr {
+		for j, c := range hdr {
 			if strings.ToLower(c) == strings.ToLower(name) {
-				vars[j] = str.Field(i).Addr().Interface()
+				columns[i] = j
 				continue outer
 			}
 		}
 
-		for j, c := range r.hdr {
+		for j, c := range hdr {
 			if strings.ToLower(strings.Replace(c, "_", " ", -1)) == strings.ToLower(strings.Replace(name, "_", " ", -1)) {
-				vars[j] = str.Field(i).Addr().Interface()
+				columns[i] = j
 				continue outer
 			}
 		}
 
-		return errors.Errorf("csvx.Reader.ScanStruct: couldn't find column in %v for field %s", r.hdr, f.Name)
+		return nil, errors.Errorf("couldn't find column in %v for field %s", hdr, f.Name)
 	}
 
-	if err := r.Scan(vars...); err != nil {
-		return errors.Wrap(err, "csvx.Reader.ScanStruct")
+	fm := &structFieldMap{hdr: key, columns: columns}
+
+	structFieldMapCache.Store(typ, fm)
+
+	return fm, nil
+}
+
+// scanStructRow implements the decoding logic behind Reader.ScanStruct, but
+// operates on a plain header/row pair instead of reading Reader state, for
+// the same reason scanRow does: decodeInto hands rows to worker goroutines
+// that don't share a single Reader.
+func scanStructRow(hdr []string, row []string, tz *time.Location, out interface{}) error {
+	ptr := reflect.ValueOf(out)
+	if ptr.Kind() != reflect.Ptr {
+		return errors.Errorf("expected out to be pointer; was instead %s", ptr.Kind())
+	}
+
+	str := reflect.Indirect(ptr)
+	if str.Kind() != reflect.Struct {
+		return errors.Errorf("expected out to be pointer to struct; was instead pointer to %s", str.Kind())
+	}
+
+	fm, err := structFieldMapFor(str.Type(), hdr)
+	if err != nil {
+		return err
+	}
+
+	vars := make([]interface{}, len(hdr))
+	for i, j := range fm.columns {
+		if j < 0 {
+			continue
+		}
+
+		vars[j] = str.Field(i).Addr().Interface()
+	}
+
+	return scanRow(row, tz, vars...)
+}
+
+// Decode reads every remaining row from a Reader built from opts and
+// decodes it into a new element of the slice pointed to by out, which must
+// be a pointer to a slice of structs. Rows are dispatched across a pool of
+// worker goroutines (see WithWorkers) and reassembled in their original
+// order before being appended, so increasing the worker count speeds up
+// the reflection-heavy decode step without reordering the result.
+//
+// Decode stops at the first error, whether it comes from reading the CSV
+// or from decoding a row, and returns it wrapped; an error that a handler
+// installed with WithErrorHandler swallows skips that row instead. No row
+// after the first error is appended, although the rest of the input is
+// still read (see decodeInto).
+func Decode(out interface{}, opts ...Option) error {
+	ov := reflect.ValueOf(out)
+	if ov.Kind() != reflect.Ptr || ov.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("csvx.Decode: out must be a pointer to a slice; was instead %T", out)
+	}
+
+	rd, err := NewReader(opts...)
+	if err != nil {
+		return errors.Wrap(err, "csvx.Decode")
+	}
+	defer rd.Close()
+
+	sv := ov.Elem()
+
+	if err := rd.decodeInto(sv.Type().Elem(), func(v reflect.Value) {
+		sv.Set(reflect.Append(sv, v))
+	}); err != nil {
+		return errors.Wrap(err, "csvx.Decode")
+	}
+
+	return nil
+}
+
+// Stream is like Decode, but sends decoded values to a channel as they
+// become available instead of collecting them into a slice. out must be a
+// send-only or bidirectional channel of struct values. Stream closes out
+// once every row has been decoded and sent, or an error occurs.
+func Stream(out interface{}, opts ...Option) error {
+	cv := reflect.ValueOf(out)
+	if cv.Kind() != reflect.Chan || cv.Type().ChanDir() == reflect.RecvDir {
+		return errors.Errorf("csvx.Stream: out must be a send-only or bidirectional channel; was instead %T", out)
+	}
+
+	defer cv.Close()
+
+	rd, err := NewReader(opts...)
+	if err != nil {
+		return errors.Wrap(err, "csvx.Stream")
+	}
+	defer rd.Close()
+
+	if err := rd.decodeInto(cv.Type().Elem(), func(v reflect.Value) {
+		cv.Send(v)
+	}); err != nil {
+		return errors.Wrap(err, "csvx.Stream")
+	}
+
+	return nil
+}
+
+// decodeInto drives the read loop for Decode and Stream. It reads rows on
+// the calling goroutine, fans them out to r.workers (or 1, if unset) worker
+// goroutines for decoding into a new value of type et, then reassembles the
+// results in their original order and hands each one to emit in turn.
+//
+// A row that encoding/csv itself couldn't read (malformed quoting, wrong
+// field count, etc.) is treated the same as one that failed to decode: it
+// never reaches emit, and is reported through rowError like a Scan/
+// ScanStruct error would be, so a WithErrorHandler installed alongside
+// Decode/Stream gets a chance to log-and-skip it. The first such error
+// that errHandler doesn't swallow is recorded and returned once every
+// in-flight row has been accounted for; rows after the failing one are
+// still read and decoded (to drain the worker pool and keep line numbers
+// accurate) but are never passed to emit. A read error other than a
+// *csv.ParseError ends reading there, since encoding/csv can't continue
+// past it.
+func (r *Reader) decodeInto(et reflect.Type, emit func(reflect.Value)) error {
+	workers := r.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	type job struct {
+		idx     int
+		line    int
+		row     []string
+		readErr error
+	}
+
+	type result struct {
+		idx  int
+		line int
+		val  reflect.Value
+		err  error
+	}
+
+	jobs := make(chan job, workers)
+	results := make(chan result, workers)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				if j.readErr != nil {
+					results <- result{idx: j.idx, line: j.line, err: &rowReadError{err: j.readErr}}
+					continue
+				}
+
+				pv := reflect.New(et)
+				err := scanStructRow(r.hdr, j.row, r.tz, pv.Interface())
+
+				results <- result{idx: j.idx, line: j.line, val: pv.Elem(), err: err}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	go func() {
+		defer close(jobs)
+
+		for idx := 0; r.Next(); idx++ {
+			j := job{idx: idx, line: r.line}
+
+			if r.err != nil {
+				j.readErr = r.err
+			} else {
+				j.row = append([]string(nil), r.row...)
+			}
+
+			jobs <- j
+
+			// only a *csv.ParseError leaves encoding/csv able to carry on
+			// with the next line; any other read error would just repeat
+			if _, ok := r.err.(*csv.ParseError); r.err != nil && !ok {
+				return
+			}
+		}
+	}()
+
+	pending := make(map[int]result)
+	next := 0
+	var firstErr error
+
+	for res := range results {
+		pending[res.idx] = res
+
+		for {
+			v, ok := pending[next]
+			if !ok {
+				break
+			}
+
+			delete(pending, next)
+			next++
+
+			if firstErr != nil {
+				continue
+			}
+
+			if v.err != nil {
+				if err := r.rowError(v.line, v.err); err != nil {
+					firstErr = err
+				}
+
+				continue
+			}
+
+			emit(v.val)
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
 	}
 
 	return nil
@@ -346,6 +845,72 @@ func (r *Reader) Close() error {
 	return errors.Wrap(r.cl(), "csvx.Reader.Close")
 }
 
+var byteUnits = []string{"B", "KB", "MB", "GB", "TB", "PB"}
+
+func byteUnitStr(n int64) string {
+	var unit string
+	size := float64(n)
+	for i := 1; i < len(byteUnits); i++ {
+		if size < 1000 {
+			unit = byteUnits[i-1]
+			break
+		}
+
+		size = size / 1000
+	}
+
+	return fmt.Sprintf("%.3g %s", size, unit)
+}
+
+func timeRemainingFormatter(c int) ioprogress.DrawTextFormatFunc {
+	var (
+		rates        = make([]int64, c)
+		startTime    = time.Now()
+		lastTime     = startTime
+		lastProgress int64
+	)
+
+	var i int
+	return func(progress, total int64) string {
+		thisTime := time.Now()
+		block := progress - lastProgress
+
+		dur := thisTime.Sub(lastTime)
+		if dur != 0 {
+			rates[i%c] = int64(float64(block) / float64(dur/time.Second))
+			i++
+		}
+
+		lastTime = thisTime
+		lastProgress = progress
+
+		var averageRate, j int64
+		for _, r := range rates {
+			if r == 0 {
+				continue
+			}
+
+			averageRate += r
+
+			j++
+		}
+		if j != 0 {
+			averageRate /= j
+		}
+
+		var remaining time.Duration
+		if averageRate != 0 {
+			remaining = time.Duration((total-progress)/averageRate) * time.Second
+		}
+
+		if progress == total {
+			return fmt.Sprintf("read %s in %s", byteUnitStr(total), time.Now().Sub(startTime))
+		}
+
+		return fmt.Sprintf("%s/%s (%s/s; %s estimated)", byteUnitStr(progress), byteUnitStr(total), byteUnitStr(averageRate), remaining)
+	}
+}
+
 func FindColumns(row []string, names ...string) (map[string]int, error) {
 	m := make(map[string]int)
 	var missing []string
@@ -0,0 +1,50 @@
+package csvx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeStopsOnMalformedRow(t *testing.T) {
+	type row struct {
+		A string `csv:"a"`
+		B string `csv:"b"`
+	}
+
+	var out []row
+	err := Decode(&out, FromReader(strings.NewReader("a,b\nfoo,bar\nbaz\nqux,quux\n")))
+	if err == nil {
+		t.Fatalf("Decode: expected an error for the malformed row, got nil (out=%+v)", out)
+	}
+
+	if len(out) != 1 || out[0] != (row{A: "foo", B: "bar"}) {
+		t.Errorf("Decode: got %+v, want only the row before the malformed one", out)
+	}
+}
+
+func TestDecodeSkipsMalformedRowWithErrorHandler(t *testing.T) {
+	type row struct {
+		A string `csv:"a"`
+		B string `csv:"b"`
+	}
+
+	var handled []RowError
+
+	var out []row
+	err := Decode(&out, FromReader(strings.NewReader("a,b\nfoo,bar\nbaz\nqux,quux\n")), WithErrorHandler(func(re RowError) error {
+		handled = append(handled, re)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	want := []row{{A: "foo", B: "bar"}, {A: "qux", B: "quux"}}
+	if len(out) != len(want) || out[0] != want[0] || out[1] != want[1] {
+		t.Errorf("Decode: got %+v, want %+v", out, want)
+	}
+
+	if len(handled) != 1 || handled[0].Line != 3 {
+		t.Errorf("errHandler: got %+v, want exactly one call for line 3", handled)
+	}
+}
@@ -0,0 +1,101 @@
+package csvx
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFromFileDecompressesBzip2(t *testing.T) {
+	if _, err := exec.LookPath("bzip2"); err != nil {
+		t.Skip("bzip2 binary not available")
+	}
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "data.csv")
+
+	if err := os.WriteFile(plain, []byte("a,b\n1,2\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if out, err := exec.Command("bzip2", "-k", plain).CombinedOutput(); err != nil {
+		t.Fatalf("bzip2: %v: %s", err, out)
+	}
+
+	rd, err := NewReader(FromFile(plain + ".bz2"))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	defer rd.Close()
+
+	if !rd.Next() {
+		t.Fatalf("Next: expected a row, got none (err=%v)", rd.err)
+	}
+
+	var a, b string
+	if err := rd.Scan(&a, &b); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	if a != "1" || b != "2" {
+		t.Errorf("Scan: got a=%q b=%q, want a=%q b=%q", a, b, "1", "2")
+	}
+}
+
+func TestRegisterDecompressorCustomFormat(t *testing.T) {
+	const marker = "REVERSED:"
+
+	RegisterDecompressor(".rev", func(r io.Reader) (io.Reader, error) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		s := strings.TrimPrefix(string(b), marker)
+		runes := []rune(s)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+
+		return strings.NewReader(string(runes)), nil
+	})
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "data.csv.rev")
+
+	content := marker + reverseString("a,b\n1,2\n")
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	rd, err := NewReader(FromFile(filename))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	defer rd.Close()
+
+	if !rd.Next() {
+		t.Fatalf("Next: expected a row, got none (err=%v)", rd.err)
+	}
+
+	var a, b string
+	if err := rd.Scan(&a, &b); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	if a != "1" || b != "2" {
+		t.Errorf("Scan: got a=%q b=%q, want a=%q b=%q", a, b, "1", "2")
+	}
+}
+
+func reverseString(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes)
+}
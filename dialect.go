@@ -0,0 +1,193 @@
+package csvx
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Dialect describes the low-level syntax of a CSV file. Comma, Comment,
+// LazyQuotes, TrimLeadingSpace and FieldsPerRecord map directly onto the
+// matching fields of encoding/csv.Reader and are applied to it by
+// WithDialect. Quote and HasHeader are only ever set by SniffDialect, as
+// informational output: encoding/csv always expects '"' as its quote
+// character (there's no field to reconfigure it), and Reader always reads
+// the first row as a header, so neither can actually be applied back.
+type Dialect struct {
+	Comma            rune
+	Comment          rune
+	LazyQuotes       bool
+	TrimLeadingSpace bool
+	FieldsPerRecord  int
+
+	Quote     rune
+	HasHeader bool
+}
+
+// WithDialect configures the underlying encoding/csv.Reader according to
+// d, for input that isn't the default comma-separated, strict-quote,
+// header-having dialect. A zero Comma is treated as "leave the
+// encoding/csv default (',') in place" rather than an explicit NUL
+// delimiter. See SniffDialect for a way to build a Dialect automatically.
+func WithDialect(d Dialect) Option {
+	return func(rd *Reader) error {
+		rd.dialect = &d
+
+		return nil
+	}
+}
+
+var dialectCandidates = []rune{',', ';', '\t', '|'}
+
+// SniffDialect inspects up to the first 64KB of r to guess its dialect,
+// then seeks r back to the start so it can be handed straight to
+// NewReader (via FromReader). Callers whose source isn't seekable should
+// buffer the sniffed prefix themselves (e.g. with a bufio.Reader's Peek,
+// wrapped in a bytes.Reader) rather than consuming it twice.
+//
+// The delimiter is picked from Comma/semicolon/tab/pipe by scoring how
+// consistent the resulting field count per line is for each candidate;
+// the one that keeps the most lines at the same field count wins. The
+// quote character is assumed to be the standard '"' unless the sample
+// contains no '"' at all and at least one single quote - anywhere, not only
+// around a field, so an apostrophe inside a value counts too. Whether a
+// header is present is guessed by comparing how numeric row 0 is against
+// how numeric the rows after it are: a header row is typically far less
+// numeric than the data that follows it.
+func SniffDialect(r io.ReadSeeker) (Dialect, error) {
+	const sniffBytes = 64 * 1024
+
+	buf := make([]byte, sniffBytes)
+
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Dialect{}, errors.Wrap(err, "csvx.SniffDialect")
+	}
+
+	buf = buf[:n]
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return Dialect{}, errors.Wrap(err, "csvx.SniffDialect")
+	}
+
+	text := string(buf)
+
+	lines := strings.Split(text, "\n")
+	if n == sniffBytes {
+		// the last line may have been truncated by the sniff window
+		lines = lines[:len(lines)-1]
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, "\r")
+	}
+
+	comma := sniffComma(lines)
+
+	quote := '"'
+	if !strings.ContainsRune(text, '"') && strings.ContainsRune(text, '\'') {
+		quote = '\''
+	}
+
+	return Dialect{
+		Comma:     comma,
+		Quote:     quote,
+		HasHeader: sniffHasHeader(lines, comma),
+	}, nil
+}
+
+// sniffComma scores each candidate delimiter by what fraction of lines
+// share that delimiter's most common per-line field count, and returns
+// the highest-scoring one. Delimiters that never split any line into more
+// than one field are never chosen - including comma, which would
+// otherwise win every tie on a file that never contains one, by virtue of
+// trivially scoring a perfect 1.0 for "1 field per line, every time".
+// If no candidate splits any line, comma is returned as the default.
+func sniffComma(lines []string) rune {
+	best := ','
+	bestScore := -1.0
+
+	for _, cand := range dialectCandidates {
+		counts := make(map[int]int)
+		total := 0
+		multiField := false
+
+		for _, l := range lines {
+			if l == "" {
+				continue
+			}
+
+			fields := strings.Count(l, string(cand)) + 1
+			if fields > 1 {
+				multiField = true
+			}
+
+			counts[fields]++
+			total++
+		}
+
+		if total == 0 || !multiField {
+			continue
+		}
+
+		var mode int
+		for _, c := range counts {
+			if c > mode {
+				mode = c
+			}
+		}
+
+		score := float64(mode) / float64(total)
+		if score > bestScore {
+			bestScore = score
+			best = cand
+		}
+	}
+
+	return best
+}
+
+// sniffHasHeader compares the fraction of numeric-looking fields in the
+// first line against the average over the rest, on the theory that a
+// header row's column names read as text while at least some data columns
+// parse as numbers.
+func sniffHasHeader(lines []string, comma rune) bool {
+	var rows [][]string
+	for _, l := range lines {
+		if l == "" {
+			continue
+		}
+
+		rows = append(rows, strings.Split(l, string(comma)))
+	}
+
+	if len(rows) < 2 {
+		return true
+	}
+
+	first := numericFraction(rows[0])
+
+	var rest float64
+	for _, row := range rows[1:] {
+		rest += numericFraction(row)
+	}
+	rest /= float64(len(rows) - 1)
+
+	return first < rest-0.2
+}
+
+func numericFraction(row []string) float64 {
+	if len(row) == 0 {
+		return 0
+	}
+
+	var n int
+	for _, c := range row {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(c), 64); err == nil {
+			n++
+		}
+	}
+
+	return float64(n) / float64(len(row))
+}
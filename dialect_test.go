@@ -0,0 +1,30 @@
+package csvx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSniffDialectNonCommaDelimiters(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  rune
+	}{
+		{"semicolon", "name;age;city\nalice;30;nyc\nbob;25;la\ncarol;40;sf\n", ';'},
+		{"tab", "name\tage\tcity\nalice\t30\tnyc\nbob\t25\tla\ncarol\t40\tsf\n", '\t'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := SniffDialect(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("SniffDialect: %v", err)
+			}
+
+			if d.Comma != tt.want {
+				t.Errorf("Comma: got %q, want %q", d.Comma, tt.want)
+			}
+		})
+	}
+}
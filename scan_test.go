@@ -0,0 +1,30 @@
+package csvx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanContinuesAfterHandledColumnError(t *testing.T) {
+	rd, err := NewReader(
+		FromReader(strings.NewReader("a,b,c\nnotanint,hello,world\n")),
+		WithErrorHandler(func(RowError) error { return nil }),
+	)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+
+	if !rd.Next() {
+		t.Fatalf("Next: expected a row, got none (err=%v)", rd.err)
+	}
+
+	var a int
+	var b, c string
+	if err := rd.Scan(&a, &b, &c); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	if a != 0 || b != "hello" || c != "world" {
+		t.Errorf("Scan: got a=%d b=%q c=%q, want a=0 b=%q c=%q", a, b, c, "hello", "world")
+	}
+}
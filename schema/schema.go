@@ -0,0 +1,292 @@
+// Package schema infers a Go struct definition from the shape of a CSV
+// file, so that a new data source can be wired up to csvx.Reader.ScanStruct
+// without having to eyeball the header row and guess at column types by
+// hand.
+package schema
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"fknsrs.biz/p/civil"
+	"fknsrs.biz/p/timex"
+)
+
+// Kind is the inferred type of a column.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindFloat64
+	KindBool
+	KindTime
+	KindDate
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindInt:
+		return "int"
+	case KindFloat64:
+		return "float64"
+	case KindBool:
+		return "bool"
+	case KindTime:
+		return "time.Time"
+	case KindDate:
+		return "civil.Date"
+	default:
+		return "string"
+	}
+}
+
+// GoType returns the Go type used to represent the kind, wrapped in a
+// pointer when nullable is true. string and bool are never pointerized,
+// since an empty cell round-trips through csvx.Reader.Scan as their zero
+// value already; int, float64, time.Time and civil.Date need a pointer to
+// distinguish a missing cell from a genuine zero.
+func (k Kind) GoType(nullable bool) string {
+	switch k {
+	case KindInt:
+		if nullable {
+			return "*int"
+		}
+		return "int"
+	case KindFloat64:
+		if nullable {
+			return "*float64"
+		}
+		return "float64"
+	case KindTime:
+		if nullable {
+			return "*time.Time"
+		}
+		return "time.Time"
+	case KindDate:
+		if nullable {
+			return "*civil.Date"
+		}
+		return "civil.Date"
+	case KindBool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// Column describes one inferred column.
+type Column struct {
+	Name     string
+	Kind     Kind
+	Nullable bool
+}
+
+// Schema is the result of Infer: an ordered list of columns matching the
+// CSV's header row.
+type Schema struct {
+	Columns []Column
+}
+
+type inferConfig struct {
+	sampleRows int
+}
+
+// InferOption configures Infer.
+type InferOption func(*inferConfig)
+
+// WithSampleRows sets how many rows after the header Infer reads before
+// settling on a kind for each column. The default is 1000.
+func WithSampleRows(n int) InferOption {
+	return func(c *inferConfig) {
+		c.sampleRows = n
+	}
+}
+
+// Infer reads a CSV's header and up to InferOption's sample row count,
+// and guesses a Kind and nullability for each column. Promotion between
+// samples is conservative: a column only ever widens (int -> float64,
+// or anything -> string on the first disagreement), never narrows, and
+// is marked nullable as soon as a single sampled cell is empty.
+func Infer(r io.Reader, opts ...InferOption) (*Schema, error) {
+	cfg := inferConfig{sampleRows: 1000}
+	for _, fn := range opts {
+		fn(&cfg)
+	}
+
+	cr := csv.NewReader(r)
+
+	hdr, err := cr.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "schema.Infer: couldn't read header")
+	}
+
+	kinds := make([]Kind, len(hdr))
+	set := make([]bool, len(hdr))
+	nullable := make([]bool, len(hdr))
+
+	for n := 0; n < cfg.sampleRows; n++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "schema.Infer")
+		}
+
+		for i, c := range row {
+			if i >= len(hdr) {
+				break
+			}
+
+			c = strings.TrimSpace(c)
+			if c == "" {
+				nullable[i] = true
+				continue
+			}
+
+			k := kindOf(c)
+
+			if !set[i] {
+				kinds[i] = k
+				set[i] = true
+				continue
+			}
+
+			kinds[i] = promote(kinds[i], k)
+		}
+	}
+
+	columns := make([]Column, len(hdr))
+	for i, name := range hdr {
+		columns[i] = Column{Name: name, Kind: kinds[i], Nullable: nullable[i]}
+	}
+
+	return &Schema{Columns: columns}, nil
+}
+
+func kindOf(s string) Kind {
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return KindInt
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return KindFloat64
+	}
+	// only spellings csvx.Reader.Scan accepts for a bool ("1" and "0" are
+	// already taken as ints above)
+	switch s {
+	case "t", "true", "f", "false":
+		return KindBool
+	}
+	if _, err := civil.ParseDate(s); err == nil {
+		return KindDate
+	}
+	if _, err := timex.ParseDefaultsInLocation(s, nil); err == nil {
+		return KindTime
+	}
+
+	return KindString
+}
+
+// promote widens a and b to a single kind, preferring the narrowest type
+// that can represent both. Only int->float64 is a true widening; any
+// other disagreement (including bool/time/date mismatches) falls back to
+// string, since there's no intermediate representation for those.
+func promote(a, b Kind) Kind {
+	if a == b {
+		return a
+	}
+
+	if (a == KindInt && b == KindFloat64) || (a == KindFloat64 && b == KindInt) {
+		return KindFloat64
+	}
+
+	return KindString
+}
+
+// Generate writes a Go source file declaring a struct named typeName in
+// package pkg, with one field per column of s, tagged so that it can be
+// passed straight to csvx.Reader.ScanStruct.
+func Generate(w io.Writer, pkg, typeName string, s *Schema) error {
+	var needsTime, needsDate bool
+	for _, c := range s.Columns {
+		needsTime = needsTime || c.Kind == KindTime
+		needsDate = needsDate || c.Kind == KindDate
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+
+	if needsTime || needsDate {
+		fmt.Fprintln(&b, "import (")
+		if needsTime {
+			fmt.Fprintln(&b, "\t\"time\"")
+		}
+		if needsDate {
+			fmt.Fprintln(&b, "\n\t\"fknsrs.biz/p/civil\"")
+		}
+		fmt.Fprintln(&b, ")")
+		fmt.Fprintln(&b)
+	}
+
+	fmt.Fprintf(&b, "type %s struct {\n", typeName)
+
+	seen := make(map[string]bool)
+	for i, c := range s.Columns {
+		name := goFieldName(c.Name)
+
+		// two columns can normalize to the same Go identifier (e.g. "Name"
+		// and "name"); suffix the later one with its column index so the
+		// generated struct still compiles.
+		if seen[name] {
+			name = fmt.Sprintf("%s%d", name, i)
+		}
+		seen[name] = true
+
+		fmt.Fprintf(&b, "\t%s %s `csv:%q`\n", name, c.Kind.GoType(c.Nullable), c.Name)
+	}
+	fmt.Fprintln(&b, "}")
+
+	_, err := io.WriteString(w, b.String())
+
+	return errors.Wrap(err, "schema.Generate")
+}
+
+// goFieldName converts a CSV column name (often snake_case or containing
+// spaces) into an exported Go identifier.
+func goFieldName(col string) string {
+	var b strings.Builder
+
+	upper := true
+	for _, r := range col {
+		switch {
+		case r == '_' || r == ' ' || r == '-':
+			upper = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				b.WriteRune(unicode.ToUpper(r))
+				upper = false
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+
+	name := b.String()
+	if name == "" {
+		return "Field"
+	}
+
+	if unicode.IsDigit(rune(name[0])) {
+		name = "F" + name
+	}
+
+	return name
+}
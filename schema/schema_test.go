@@ -0,0 +1,68 @@
+package schema
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestInferPromotesAcrossSamples(t *testing.T) {
+	csv := "id,amount,name,active\n1,10,alice,true\n2,10.5,bob,false\n"
+
+	s, err := Infer(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Infer: %v", err)
+	}
+
+	want := []Column{
+		{Name: "id", Kind: KindInt},
+		{Name: "amount", Kind: KindFloat64},
+		{Name: "name", Kind: KindString},
+		{Name: "active", Kind: KindBool},
+	}
+
+	if len(s.Columns) != len(want) {
+		t.Fatalf("Columns: got %+v, want %+v", s.Columns, want)
+	}
+
+	for i, c := range s.Columns {
+		if c.Name != want[i].Name || c.Kind != want[i].Kind {
+			t.Errorf("Columns[%d]: got %+v, want %+v", i, c, want[i])
+		}
+	}
+}
+
+func TestInferMarksNullableOnEmptyCell(t *testing.T) {
+	csv := "id,note\n1,hello\n2,\n"
+
+	s, err := Infer(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Infer: %v", err)
+	}
+
+	if s.Columns[1].Nullable != true {
+		t.Errorf("Columns[1].Nullable: got false, want true")
+	}
+}
+
+func TestGenerateDedupesCollidingFieldNames(t *testing.T) {
+	s := &Schema{Columns: []Column{
+		{Name: "Name", Kind: KindString},
+		{Name: "name", Kind: KindString},
+	}}
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, "example", "Row", s); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "row.go", buf.String(), 0); err != nil {
+		t.Fatalf("generated source doesn't parse: %v\n%s", err, buf.String())
+	}
+
+	if strings.Count(buf.String(), "Name ") != 1 {
+		t.Errorf("expected the second \"Name\" field to be disambiguated, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,355 @@
+package csvx
+
+import (
+	"compress/gzip"
+	"encoding"
+	"encoding/csv"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"fknsrs.biz/p/civil"
+)
+
+// Marshaler is the write-side counterpart to Scanner: types that
+// implement it control their own representation when written by
+// Writer.WriteStruct or Writer.Write.
+type Marshaler interface {
+	MarshalString() (string, error)
+}
+
+// Writer mirrors Reader, writing rows (and, via WriteStruct, struct
+// values) out as CSV.
+type Writer struct {
+	fd        io.Writer
+	wr        *csv.Writer
+	cl        func() error
+	tz        *time.Location
+	hdr       []string
+	delimiter rune
+	crlf      bool
+}
+
+type WriterOption func(wr *Writer) error
+
+// ToFile opens filename for writing and uses it as the Writer's
+// destination. If filename ends in ".gz", the output is gzip-compressed
+// as it's written.
+func ToFile(filename string) WriterOption {
+	return func(wr *Writer) error {
+		fd, err := os.Create(filename)
+		if err != nil {
+			return errors.Wrap(err, "csvx.ToFile")
+		}
+
+		wr.fd = fd
+		wr.cl = fd.Close
+
+		if strings.HasSuffix(filename, ".gz") {
+			gz := gzip.NewWriter(wr.fd)
+			wr.fd = gz
+
+			fdClose := wr.cl
+			wr.cl = func() error {
+				if err := gz.Close(); err != nil {
+					return err
+				}
+
+				return fdClose()
+			}
+		}
+
+		return nil
+	}
+}
+
+// ToWriter uses fd as the Writer's destination. If fd implements
+// io.Closer, it's closed when the Writer is closed.
+func ToWriter(fd io.Writer) WriterOption {
+	return func(wr *Writer) error {
+		wr.fd = fd
+
+		if cl, ok := fd.(io.Closer); ok {
+			wr.cl = cl.Close
+		}
+
+		return nil
+	}
+}
+
+// WithWriterTZ sets the timezone that time.Time and *time.Time values are
+// converted to before being formatted. The mirror of Reader's WithTZ.
+func WithWriterTZ(tz *time.Location) WriterOption {
+	return func(wr *Writer) error {
+		wr.tz = tz
+
+		return nil
+	}
+}
+
+// WithDelimiter sets the field delimiter used by the underlying
+// encoding/csv.Writer. The default is a comma.
+func WithDelimiter(r rune) WriterOption {
+	return func(wr *Writer) error {
+		wr.delimiter = r
+
+		return nil
+	}
+}
+
+// WithCRLF makes the Writer terminate rows with "\r\n" instead of "\n".
+func WithCRLF() WriterOption {
+	return func(wr *Writer) error {
+		wr.crlf = true
+
+		return nil
+	}
+}
+
+func NewWriter(opts ...WriterOption) (*Writer, error) {
+	w := &Writer{}
+
+	for _, fn := range opts {
+		if err := fn(w); err != nil {
+			return nil, errors.Wrap(err, "csvx.NewWriter")
+		}
+	}
+
+	if w.fd == nil {
+		return nil, errors.Errorf("csvx.NewWriter: fd is nil after option processing")
+	}
+
+	w.wr = csv.NewWriter(w.fd)
+
+	if w.delimiter != 0 {
+		w.wr.Comma = w.delimiter
+	}
+
+	w.wr.UseCRLF = w.crlf
+
+	return w, nil
+}
+
+// Write writes a single row built from vals, formatting each value the
+// same way Reader.Scan parses one: strings and bools are formatted
+// directly, numeric and time types use their standard textual form, and
+// anything else falls back to Marshaler or encoding.TextMarshaler.
+func (w *Writer) Write(vals ...interface{}) error {
+	row := make([]string, len(vals))
+
+	for i, v := range vals {
+		c, err := formatValue(v, w.tz)
+		if err != nil {
+			return errors.Wrapf(err, "csvx.Writer.Write (index %d)", i)
+		}
+
+		row[i] = c
+	}
+
+	return errors.Wrap(w.wr.Write(row), "csvx.Writer.Write")
+}
+
+// WriteStruct writes v, which must be a struct or pointer to struct, as a
+// single row. The header row is derived from v's `csv:"name"` tags (or
+// field names, if untagged) and written once, before the first row; a
+// tag of `csv:"-"` excludes a field entirely, and `csv:"name,omitempty"`
+// writes an empty cell instead of a zero value for that field.
+func (w *Writer) WriteStruct(v interface{}) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() == reflect.Ptr {
+		ptr = ptr.Elem()
+	}
+
+	if ptr.Kind() != reflect.Struct {
+		return errors.Errorf("csvx.Writer.WriteStruct: expected v to be a struct or pointer to struct; was instead %T", v)
+	}
+
+	fm := writerFieldMapFor(ptr.Type())
+
+	if w.hdr == nil {
+		w.hdr = fm.names
+
+		if err := w.wr.Write(w.hdr); err != nil {
+			return errors.Wrap(err, "csvx.Writer.WriteStruct")
+		}
+	}
+
+	row := make([]string, len(fm.fields))
+
+	for i, fi := range fm.fields {
+		fv := ptr.Field(fi)
+
+		if fm.omitempty[i] && fv.IsZero() {
+			continue
+		}
+
+		c, err := formatValue(fv.Interface(), w.tz)
+		if err != nil {
+			return errors.Wrapf(err, "csvx.Writer.WriteStruct (field %s)", ptr.Type().Field(fi).Name)
+		}
+
+		row[i] = c
+	}
+
+	return errors.Wrap(w.wr.Write(row), "csvx.Writer.WriteStruct")
+}
+
+// Flush writes any buffered rows to the underlying writer.
+func (w *Writer) Flush() error {
+	w.wr.Flush()
+
+	return errors.Wrap(w.wr.Error(), "csvx.Writer.Flush")
+}
+
+// Close flushes any buffered rows and closes the underlying destination,
+// if it was opened by ToFile or implements io.Closer.
+func (w *Writer) Close() error {
+	w.wr.Flush()
+
+	err := w.wr.Error()
+
+	if w.cl != nil {
+		if cerr := w.cl(); err == nil {
+			err = cerr
+		}
+	}
+
+	return errors.Wrap(err, "csvx.Writer.Close")
+}
+
+// writerFieldMap is the write-side counterpart to structFieldMap: the
+// ordered list of struct fields to emit as columns, their header names,
+// and which of them are marked omitempty.
+type writerFieldMap struct {
+	names     []string
+	fields    []int
+	omitempty []bool
+}
+
+var writerFieldMapCache sync.Map // map[reflect.Type]*writerFieldMap
+
+func writerFieldMapFor(typ reflect.Type) *writerFieldMap {
+	if v, ok := writerFieldMapCache.Load(typ); ok {
+		return v.(*writerFieldMap)
+	}
+
+	fm := &writerFieldMap{}
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+
+		a := strings.Split(f.Tag.Get("csv"), ",")
+		if a[0] == "-" {
+			continue
+		}
+
+		name := f.Name
+		if a[0] != "" {
+			name = a[0]
+		}
+
+		var omitempty bool
+		for _, opt := range a[1:] {
+			if opt == "omitempty" {
+				omitempty = true
+			}
+		}
+
+		fm.names = append(fm.names, name)
+		fm.fields = append(fm.fields, i)
+		fm.omitempty = append(fm.omitempty, omitempty)
+	}
+
+	writerFieldMapCache.Store(typ, fm)
+
+	return fm
+}
+
+// formatValue implements the formatting logic behind Write and WriteStruct,
+// formatting a single value as the textual form Scan would have parsed it
+// from. It's the mirror image of the type switch in scanField.
+func formatValue(v interface{}, tz *time.Location) (string, error) {
+	switch e := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return e, nil
+	case int:
+		return strconv.FormatInt(int64(e), 10), nil
+	case *int:
+		if e == nil {
+			return "", nil
+		}
+
+		return strconv.FormatInt(int64(*e), 10), nil
+	case float64:
+		return strconv.FormatFloat(e, 'f', -1, 64), nil
+	case *float64:
+		if e == nil {
+			return "", nil
+		}
+
+		return strconv.FormatFloat(*e, 'f', -1, 64), nil
+	case time.Time:
+		return formatTime(e, tz), nil
+	case *time.Time:
+		if e == nil {
+			return "", nil
+		}
+
+		return formatTime(*e, tz), nil
+	case civil.Date:
+		return e.String(), nil
+	case *civil.Date:
+		if e == nil {
+			return "", nil
+		}
+
+		return e.String(), nil
+	case bool:
+		if e {
+			return "true", nil
+		}
+
+		return "false", nil
+	default:
+		if m, ok := v.(Marshaler); ok {
+			return m.MarshalString()
+		}
+
+		if m, ok := v.(encoding.TextMarshaler); ok {
+			b, err := m.MarshalText()
+			if err != nil {
+				return "", err
+			}
+
+			return string(b), nil
+		}
+
+		p := reflect.ValueOf(v)
+
+		if p.Kind() == reflect.Ptr {
+			if p.IsNil() {
+				return "", nil
+			}
+
+			return formatValue(p.Elem().Interface(), tz)
+		}
+
+		return "", errors.Errorf("csvx.Writer: can't format %T", v)
+	}
+}
+
+func formatTime(t time.Time, tz *time.Location) string {
+	if tz != nil {
+		t = t.In(tz)
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,46 @@
+package csvx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteStructOmitemptyRoundTripsZeroValues(t *testing.T) {
+	type row struct {
+		A string `csv:"a"`
+		B int    `csv:"b,omitempty"`
+	}
+
+	var buf bytes.Buffer
+
+	wr, err := NewWriter(ToWriter(&buf))
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+
+	if err := wr.WriteStruct(row{A: "x", B: 0}); err != nil {
+		t.Fatalf("WriteStruct: %v", err)
+	}
+
+	if err := wr.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	rd, err := NewReader(FromReader(bytes.NewReader(buf.Bytes())))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+
+	if !rd.Next() {
+		t.Fatalf("Next: expected a row, got none (err=%v)", rd.err)
+	}
+
+	var out row
+	if err := rd.ScanStruct(&out); err != nil {
+		t.Fatalf("ScanStruct: %v", err)
+	}
+
+	if out != (row{A: "x", B: 0}) {
+		t.Errorf("ScanStruct: got %+v, want %+v", out, row{A: "x", B: 0})
+	}
+}